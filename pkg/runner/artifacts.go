@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultArtifactDirs is scraped when neither execution.ArtifactRequest nor
+// RUNNER_ARTIFACT_DIRS configure anything more specific.
+var defaultArtifactDirs = []string{
+	"dist/**/test-output",
+	"coverage",
+	"playwright-report",
+	"test-results",
+}
+
+// resolveArtifactDirs works out which directories to scrape after a run, honoring (in order)
+// execution.ArtifactRequest.Dirs, RUNNER_ARTIFACT_DIRS, and finally a sensible Nx default set.
+// RUNNER_ARTIFACT_GLOBS is always appended on top. Every entry is resolved relative to runPath
+// and expanded as a doublestar glob; entries that match nothing are skipped with a debug log
+// rather than failing the run.
+func (r *NxRunner) resolveArtifactDirs(runPath string, execution testkube.Execution) []string {
+	var entries []string
+	switch {
+	case execution.ArtifactRequest != nil && len(execution.ArtifactRequest.Dirs) > 0:
+		entries = execution.ArtifactRequest.Dirs
+	case r.Params.ArtifactDirs != "":
+		entries = strings.Split(r.Params.ArtifactDirs, ",")
+	default:
+		entries = append(entries, defaultArtifactDirs...)
+		if isCypressProject(runPath, r.Params.NxProject) {
+			entries = append(entries, "cypress/videos", "cypress/screenshots")
+		}
+	}
+
+	if r.Params.ArtifactGlobs != "" {
+		entries = append(entries, strings.Split(r.Params.ArtifactGlobs, ",")...)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern := filepath.Join(runPath, entry)
+		matches, err := doublestar.FilepathGlob(pattern)
+		if err != nil {
+			logrus.Debugf("artifact pattern %q is invalid, skipping: %v", pattern, err)
+			continue
+		}
+
+		if len(matches) == 0 {
+			logrus.Debugf("artifact path %q not found, skipping", pattern)
+			continue
+		}
+
+		dirs = append(dirs, matches...)
+	}
+
+	return dirs
+}
+
+// isCypressProject reports whether project's project.json declares a @nrwl/cypress (or
+// @nx/cypress) target, so the Cypress artifact defaults are only added where they apply.
+func isCypressProject(runPath, project string) bool {
+	if project == "" {
+		return false
+	}
+
+	found := false
+	_ = filepath.WalkDir(runPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || found || d.IsDir() || d.Name() != "project.json" {
+			return nil
+		}
+
+		data, rerr := os.ReadFile(path)
+		if rerr != nil {
+			return nil
+		}
+
+		var cfg struct {
+			Name    string `json:"name"`
+			Targets map[string]struct {
+				Executor string `json:"executor"`
+			} `json:"targets"`
+		}
+		if jerr := json.Unmarshal(data, &cfg); jerr != nil || cfg.Name != project {
+			return nil
+		}
+
+		for _, target := range cfg.Targets {
+			if strings.Contains(target.Executor, "cypress") {
+				found = true
+				return nil
+			}
+		}
+
+		return nil
+	})
+
+	return found
+}
@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestResolveArtifactDirs_DefaultsWhenNothingConfigured(t *testing.T) {
+	runPath := t.TempDir()
+	mkdirAll(t, runPath, "coverage")
+	mkdirAll(t, runPath, "test-results")
+
+	r := &NxRunner{}
+	dirs := r.resolveArtifactDirs(runPath, testkube.Execution{})
+
+	wantSuffixes := []string{"coverage", "test-results"}
+	for _, want := range wantSuffixes {
+		if !containsSuffix(dirs, want) {
+			t.Errorf("dirs = %v, want an entry ending in %q", dirs, want)
+		}
+	}
+}
+
+func TestResolveArtifactDirs_ArtifactRequestTakesPrecedence(t *testing.T) {
+	runPath := t.TempDir()
+	mkdirAll(t, runPath, "coverage")
+	mkdirAll(t, runPath, "custom-artifacts")
+
+	r := &NxRunner{Params: Params{ArtifactDirs: "coverage"}}
+	execution := testkube.Execution{ArtifactRequest: &testkube.ArtifactRequest{Dirs: []string{"custom-artifacts"}}}
+
+	dirs := r.resolveArtifactDirs(runPath, execution)
+
+	if !containsSuffix(dirs, "custom-artifacts") {
+		t.Errorf("dirs = %v, want an entry ending in %q", dirs, "custom-artifacts")
+	}
+	if containsSuffix(dirs, "coverage") {
+		t.Errorf("dirs = %v, should not fall back to RUNNER_ARTIFACT_DIRS when ArtifactRequest is set", dirs)
+	}
+}
+
+func TestResolveArtifactDirs_MissingPathsAreSkipped(t *testing.T) {
+	runPath := t.TempDir()
+
+	r := &NxRunner{Params: Params{ArtifactDirs: "does-not-exist"}}
+	dirs := r.resolveArtifactDirs(runPath, testkube.Execution{})
+
+	if len(dirs) != 0 {
+		t.Errorf("dirs = %v, want none for a path that doesn't exist", dirs)
+	}
+}
+
+func mkdirAll(t *testing.T, base string, rel string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(base, rel), 0755); err != nil {
+		t.Fatalf("creating %s: %v", rel, err)
+	}
+}
+
+func containsSuffix(dirs []string, suffix string) bool {
+	for _, d := range dirs {
+		if filepath.Base(d) == suffix {
+			return true
+		}
+	}
+	return false
+}
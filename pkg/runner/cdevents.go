@@ -0,0 +1,191 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	cdeventsapi "github.com/cdevents/sdk-go/pkg/api"
+	cdeventsv05 "github.com/cdevents/sdk-go/pkg/api/v05"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/joshdk/go-junit"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cdeventsOutcomeSuccess = "success"
+	cdeventsOutcomeFailure = "failure"
+)
+
+// CDEventsPublisher emits CDEvents describing test lifecycle so an external event bus can
+// correlate runs without polling the testkube API. It is optional: NewCDEventsPublisher
+// returns a nil publisher when no target is configured, and every Publish* method is a
+// no-op on a nil receiver so callers don't need to branch on whether CDEvents are enabled.
+type CDEventsPublisher struct {
+	target string
+	source string
+	client cloudevents.Client
+}
+
+// NewCDEventsPublisher builds a publisher that POSTs to target, an HTTP(S) endpoint. It
+// returns a nil publisher, not an error, when target is empty, so RUNNER_CDEVENTS_TARGET
+// stays optional. A `nats://` target is rejected up front with a clear error rather than
+// being silently handed to the HTTP client, since there is no NATS transport wired up yet.
+func NewCDEventsPublisher(target, source string) (*CDEventsPublisher, error) {
+	if target == "" {
+		return nil, nil
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cdevents target: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported cdevents target scheme %q: only http(s) endpoints are supported", parsed.Scheme)
+	}
+
+	client, err := cloudevents.NewClientHTTP()
+	if err != nil {
+		return nil, fmt.Errorf("creating cdevents client: %w", err)
+	}
+
+	return &CDEventsPublisher{target: target, source: source, client: client}, nil
+}
+
+// PublishQueued emits a dev.cdevents.testsuiterun.queued event for the given Nx project.
+func (p *CDEventsPublisher) PublishQueued(execution testkube.Execution, project string) error {
+	if p == nil {
+		return nil
+	}
+
+	event, err := cdeventsv05.NewTestSuiteRunQueuedEvent()
+	if err != nil {
+		return fmt.Errorf("creating testsuiterun.queued event: %w", err)
+	}
+
+	p.setSubject(event, execution, project, nil)
+
+	return p.send(event)
+}
+
+// PublishStarted emits a dev.cdevents.testsuiterun.started event for the given Nx project.
+func (p *CDEventsPublisher) PublishStarted(execution testkube.Execution, project string) error {
+	if p == nil {
+		return nil
+	}
+
+	event, err := cdeventsv05.NewTestSuiteRunStartedEvent()
+	if err != nil {
+		return fmt.Errorf("creating testsuiterun.started event: %w", err)
+	}
+
+	p.setSubject(event, execution, project, nil)
+
+	return p.send(event)
+}
+
+// PublishFinished emits a dev.cdevents.testsuiterun.finished event summarizing the suites
+// produced by the run, followed by one dev.cdevents.testcaserun.finished event per test.
+func (p *CDEventsPublisher) PublishFinished(execution testkube.Execution, project string, suites []junit.Suite) error {
+	if p == nil {
+		return nil
+	}
+
+	passed, failed := 0, 0
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			if MapStatus(test.Status) == string(testkube.PASSED_ExecutionStatus) {
+				passed++
+			} else {
+				failed++
+			}
+		}
+	}
+
+	event, err := cdeventsv05.NewTestSuiteRunFinishedEvent()
+	if err != nil {
+		return fmt.Errorf("creating testsuiterun.finished event: %w", err)
+	}
+
+	p.setSubject(event, execution, project, nil)
+	event.SetSubjectOutcome(outcome(failed == 0))
+
+	if err := p.send(event); err != nil {
+		return err
+	}
+
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			if terr := p.publishTestCaseFinished(execution, project, suite, test); terr != nil {
+				return terr
+			}
+		}
+	}
+
+	logrus.Debugf("cdevents: published testsuiterun.finished for %s (passed=%d failed=%d)", project, passed, failed)
+
+	return nil
+}
+
+func (p *CDEventsPublisher) publishTestCaseFinished(execution testkube.Execution, project string, suite junit.Suite, test junit.Test) error {
+	event, err := cdeventsv05.NewTestCaseRunFinishedEvent()
+	if err != nil {
+		return fmt.Errorf("creating testcaserun.finished event: %w", err)
+	}
+
+	// testcaserun.finished has no dedicated duration field, so the test duration travels
+	// alongside the commit/branch in custom data instead.
+	p.setSubject(event, execution, project, map[string]interface{}{
+		"durationMs": test.Duration.Milliseconds(),
+	})
+	event.SetSubjectId(fmt.Sprintf("%s/%s/%s", execution.Id, suite.Name, test.Name))
+	event.SetSubjectOutcome(outcome(MapStatus(test.Status) == string(testkube.PASSED_ExecutionStatus)))
+
+	return p.send(event)
+}
+
+func (p *CDEventsPublisher) setSubject(event cdeventsapi.CDEventWriter, execution testkube.Execution, project string, extraCustomData map[string]interface{}) {
+	event.SetSubjectId(fmt.Sprintf("%s/%s", execution.Id, project))
+	event.SetSource(p.source)
+	event.SetTimestamp(time.Now())
+
+	customData := map[string]interface{}{}
+	if repo := execution.Content.Repository; repo != nil {
+		customData["commit"] = repo.Commit
+		customData["branch"] = repo.Branch
+	}
+	for key, value := range extraCustomData {
+		customData[key] = value
+	}
+
+	if len(customData) == 0 {
+		return
+	}
+	if serr := event.SetCustomData("application/json", customData); serr != nil {
+		logrus.Errorf("cdevents: setting custom data: %v", serr)
+	}
+}
+
+func outcome(passed bool) string {
+	if passed {
+		return cdeventsOutcomeSuccess
+	}
+
+	return cdeventsOutcomeFailure
+}
+
+func (p *CDEventsPublisher) send(event cdeventsapi.CDEventReader) error {
+	ce, err := cdeventsapi.AsCloudEvent(event)
+	if err != nil {
+		return fmt.Errorf("encoding cdevent: %w", err)
+	}
+
+	ctx := cloudevents.ContextWithTarget(context.Background(), p.target)
+	if result := p.client.Send(ctx, *ce); cloudevents.IsUndelivered(result) {
+		return fmt.Errorf("sending cdevent: %w", result)
+	}
+
+	return nil
+}
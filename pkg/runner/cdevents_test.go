@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func newTestCDEventsPublisher(t *testing.T) (*CDEventsPublisher, func() []string) {
+	t.Helper()
+
+	var types []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		types = append(types, r.Header.Get("ce-type"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	publisher, err := NewCDEventsPublisher(server.URL, "testkube-executor-nx")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return publisher, func() []string { return types }
+}
+
+func testExecution() testkube.Execution {
+	return testkube.Execution{
+		Id:      "exec-1",
+		Content: &testkube.TestContent{Repository: &testkube.Repository{Commit: "abc123", Branch: "main"}},
+	}
+}
+
+func TestNewCDEventsPublisher_RejectsNonHTTPTarget(t *testing.T) {
+	if _, err := NewCDEventsPublisher("nats://example.com", "source"); err == nil {
+		t.Fatal("expected an error for a non-http(s) target")
+	}
+}
+
+func TestNewCDEventsPublisher_NilWhenTargetEmpty(t *testing.T) {
+	publisher, err := NewCDEventsPublisher("", "source")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if publisher != nil {
+		t.Fatal("expected a nil publisher when target is empty")
+	}
+}
+
+func TestPublishQueued(t *testing.T) {
+	publisher, types := newTestCDEventsPublisher(t)
+
+	if err := publisher.PublishQueued(testExecution(), "my-project"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(types()) != 1 || types()[0] != "dev.cdevents.testsuiterun.queued.0.3.0" {
+		t.Errorf("got event types %v, want a single testsuiterun.queued event", types())
+	}
+}
+
+func TestPublishStarted(t *testing.T) {
+	publisher, types := newTestCDEventsPublisher(t)
+
+	if err := publisher.PublishStarted(testExecution(), "my-project"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(types()) != 1 || types()[0] != "dev.cdevents.testsuiterun.started.0.3.0" {
+		t.Errorf("got event types %v, want a single testsuiterun.started event", types())
+	}
+}
+
+func TestPublishFinished_EmitsSuiteAndTestCaseEvents(t *testing.T) {
+	publisher, types := newTestCDEventsPublisher(t)
+
+	suites := []junit.Suite{
+		{
+			Name: "my-suite",
+			Tests: []junit.Test{
+				{Name: "test-a", Status: junit.StatusPassed, Duration: 150 * time.Millisecond},
+			},
+		},
+	}
+
+	if err := publisher.PublishFinished(testExecution(), "my-project", suites); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := types()
+	if len(got) != 2 {
+		t.Fatalf("got event types %v, want one testsuiterun.finished and one testcaserun.finished event", got)
+	}
+	if got[0] != "dev.cdevents.testsuiterun.finished.0.3.0" {
+		t.Errorf("got %q, want testsuiterun.finished", got[0])
+	}
+	if got[1] != "dev.cdevents.testcaserun.finished.0.3.0" {
+		t.Errorf("got %q, want testcaserun.finished", got[1])
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	if got := outcome(true); got != cdeventsOutcomeSuccess {
+		t.Errorf("outcome(true) = %q, want %q", got, cdeventsOutcomeSuccess)
+	}
+	if got := outcome(false); got != cdeventsOutcomeFailure {
+		t.Errorf("outcome(false) = %q, want %q", got, cdeventsOutcomeFailure)
+	}
+}
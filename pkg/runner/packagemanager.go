@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	packageManagerPnpm = "pnpm"
+	packageManagerYarn = "yarn"
+	packageManagerNpm  = "npm"
+	packageManagerBun  = "bun"
+)
+
+// detectPackageManager inspects runPath's lockfiles to figure out which package manager
+// produced the checked-out repo, returning the install command and its args. It returns an
+// empty packageManager, not an error, when no known lockfile is present so the caller can fall
+// back to the DEPENDENCY_MANAGER dependency or npm. RUNNER_PACKAGE_MANAGER, when set, is
+// applied by the caller ahead of whatever this detects.
+func detectPackageManager(runPath string) (packageManager string, installArgs []string, err error) {
+	switch {
+	case fileExists(filepath.Join(runPath, "pnpm-lock.yaml")):
+		return packageManagerPnpm, []string{"install", "--frozen-lockfile"}, nil
+	case fileExists(filepath.Join(runPath, "yarn.lock")):
+		if fileExists(filepath.Join(runPath, ".yarnrc.yml")) {
+			// Yarn >=2 (Berry) uses --immutable instead of --frozen-lockfile
+			return packageManagerYarn, []string{"install", "--immutable"}, nil
+		}
+		return packageManagerYarn, []string{"install", "--frozen-lockfile"}, nil
+	case fileExists(filepath.Join(runPath, "package-lock.json")):
+		return packageManagerNpm, []string{"ci"}, nil
+	case fileExists(filepath.Join(runPath, "bun.lockb")):
+		return packageManagerBun, []string{"install", "--frozen-lockfile"}, nil
+	default:
+		return "", nil, nil
+	}
+}
+
+// installArgsFor returns the install invocation for a package manager that was chosen
+// explicitly (RUNNER_PACKAGE_MANAGER, the legacy dependency manager, or the npm default)
+// rather than detected from a lockfile. pnpm, yarn and bun get a frozen-lockfile install and
+// npm gets `ci` when package-lock.json exists; npm without a lockfile and any other manager
+// get a plain `install`.
+func installArgsFor(packageManager, runPath string) []string {
+	switch packageManager {
+	case packageManagerPnpm:
+		return []string{"install", "--frozen-lockfile"}
+	case packageManagerYarn:
+		if fileExists(filepath.Join(runPath, ".yarnrc.yml")) {
+			// Yarn >=2 (Berry) uses --immutable instead of --frozen-lockfile
+			return []string{"install", "--immutable"}
+		}
+		return []string{"install", "--frozen-lockfile"}
+	case packageManagerBun:
+		return []string{"install", "--frozen-lockfile"}
+	case packageManagerNpm:
+		if fileExists(filepath.Join(runPath, "package-lock.json")) {
+			return []string{"ci"}
+		}
+		return []string{"install"}
+	default:
+		return []string{"install"}
+	}
+}
+
+// nxBinary returns the executable and leading args used to invoke the workspace-local nx binary
+// for the given package manager, since pnpm's non-flat node_modules layout can't resolve
+// ./node_modules/.bin/nx the way npm/yarn's flat layout does. The two are kept separate, rather
+// than joined into one string, because exec.Command (and executor.Run, which wraps it) treats
+// its command argument as a single literal executable name and does not shell-split it.
+func nxBinary(packageManager string) (string, []string) {
+	switch packageManager {
+	case packageManagerPnpm:
+		return "pnpm", []string{"exec", "nx"}
+	case packageManagerYarn:
+		return "yarn", []string{"nx"}
+	case packageManagerBun:
+		return "bun", []string{"x", "nx"}
+	case packageManagerNpm:
+		return "npx", []string{"nx"}
+	default:
+		return "./node_modules/.bin/nx", nil
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func touch(t *testing.T, dir, name string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte{}, 0644); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	cases := []struct {
+		name         string
+		lockfiles    []string
+		wantManager  string
+		wantArgsJoin string
+	}{
+		{name: "pnpm", lockfiles: []string{"pnpm-lock.yaml"}, wantManager: packageManagerPnpm, wantArgsJoin: "install --frozen-lockfile"},
+		{name: "yarn classic", lockfiles: []string{"yarn.lock"}, wantManager: packageManagerYarn, wantArgsJoin: "install --frozen-lockfile"},
+		{name: "yarn berry", lockfiles: []string{"yarn.lock", ".yarnrc.yml"}, wantManager: packageManagerYarn, wantArgsJoin: "install --immutable"},
+		{name: "npm", lockfiles: []string{"package-lock.json"}, wantManager: packageManagerNpm, wantArgsJoin: "ci"},
+		{name: "bun", lockfiles: []string{"bun.lockb"}, wantManager: packageManagerBun, wantArgsJoin: "install --frozen-lockfile"},
+		{name: "none", lockfiles: nil, wantManager: "", wantArgsJoin: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tc.lockfiles {
+				touch(t, dir, f)
+			}
+
+			manager, args, err := detectPackageManager(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if manager != tc.wantManager {
+				t.Errorf("manager = %q, want %q", manager, tc.wantManager)
+			}
+			if got := joinArgs(args); got != tc.wantArgsJoin {
+				t.Errorf("args = %q, want %q", got, tc.wantArgsJoin)
+			}
+		})
+	}
+}
+
+func TestNxBinary(t *testing.T) {
+	cases := map[string]string{
+		packageManagerPnpm: "pnpm exec nx",
+		packageManagerYarn: "yarn nx",
+		packageManagerBun:  "bun x nx",
+		packageManagerNpm:  "npx nx",
+		"":                 "./node_modules/.bin/nx",
+	}
+
+	for packageManager, want := range cases {
+		bin, args := nxBinary(packageManager)
+		got := joinArgs(append([]string{bin}, args...))
+		if got != want {
+			t.Errorf("nxBinary(%q) = %q, want %q", packageManager, got, want)
+		}
+	}
+}
+
+func joinArgs(args []string) string {
+	out := ""
+	for i, a := range args {
+		if i > 0 {
+			out += " "
+		}
+		out += a
+	}
+	return out
+}
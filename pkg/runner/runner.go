@@ -11,11 +11,24 @@ import (
 	"github.com/kubeshop/testkube/pkg/executor/scraper"
 	"github.com/kubeshop/testkube/pkg/executor/secret"
 	"github.com/sirupsen/logrus"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+const (
+	// NxModeRun executes a single `nx run <project> --target=<target>`
+	NxModeRun = "run"
+	// NxModeAffected executes `nx affected --target=<target>` across every project touched between base and head
+	NxModeAffected = "affected"
+
+	// ArgsModeAppend appends execution.Args (plus the synthesized --env list) to the default nx command
+	ArgsModeAppend = "append"
+	// ArgsModeOverride replaces the default nx command and args entirely with execution.Command/execution.Args
+	ArgsModeOverride = "override"
+)
+
 type Params struct {
 	Endpoint        string `env:"RUNNER_ENDPOINT"`
 	AccessKeyID     string `env:"RUNNER_ACCESSKEYID"`
@@ -29,12 +42,23 @@ type Params struct {
 	Datadir         string `env:"RUNNER_DATADIR"`
 	NxProject       string `env:"RUNNER_NX_PROJECT"`
 	NxCommand       string `env:"RUNNER_NX_COMMAND" envDefault:"e2e"`
+	NxMode          string `env:"RUNNER_NX_MODE" envDefault:"run"`
+	NxBase          string `env:"RUNNER_NX_BASE"`
+	NxHead          string `env:"RUNNER_NX_HEAD"`
+	CDEventsTarget  string `env:"RUNNER_CDEVENTS_TARGET"`
+	CDEventsSource  string `env:"RUNNER_CDEVENTS_SOURCE" envDefault:"testkube-executor-nx"`
+	PreRunScript    string `env:"RUNNER_PRE_RUN_SCRIPT"`
+	PostRunScript   string `env:"RUNNER_POST_RUN_SCRIPT"`
+	ArtifactDirs    string `env:"RUNNER_ARTIFACT_DIRS"`
+	ArtifactGlobs   string `env:"RUNNER_ARTIFACT_GLOBS"`
+	PackageManager  string `env:"RUNNER_PACKAGE_MANAGER"`
 }
 
 type NxRunner struct {
 	Params     Params
 	Fetcher    content.ContentFetcher
 	Scraper    scraper.Scraper
+	CDEvents   *CDEventsPublisher
 	dependency string
 }
 
@@ -47,7 +71,11 @@ func NewRunner(dependency string) (*NxRunner, error) {
 		return nil, err
 	}
 
-	if params.NxProject == "" {
+	if params.NxMode != NxModeRun && params.NxMode != NxModeAffected {
+		return nil, fmt.Errorf("nx mode must be %q or %q (got %q)", NxModeRun, NxModeAffected, params.NxMode)
+	}
+
+	if params.NxMode == NxModeRun && params.NxProject == "" {
 		return nil, errors.New("nx project must be defined (expected RUNNER_NX_PROJECT not to be empty)")
 	}
 
@@ -71,10 +99,18 @@ func NewRunner(dependency string) (*NxRunner, error) {
 	)
 	logrus.Debug("end: preparing scraper")
 
+	logrus.Debug("start: preparing cdevents publisher")
+	cdevents, err := NewCDEventsPublisher(params.CDEventsTarget, params.CDEventsSource)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debug("end: preparing cdevents publisher")
+
 	return &NxRunner{
 		Params:     params,
 		Fetcher:    fetcher,
 		Scraper:    scrpr,
+		CDEvents:   cdevents,
 		dependency: dependency,
 	}, nil
 }
@@ -112,11 +148,35 @@ func (r *NxRunner) Run(execution testkube.Execution) (result testkube.ExecutionR
 
 	logrus.Debug("start: installing local dependencies")
 	// install local dependencies
+	var packageManager string
 	if _, err := os.Stat(filepath.Join(runPath, "package.json")); err == nil {
+		detected, detectedArgs, derr := detectPackageManager(runPath)
+		if derr != nil {
+			return result, fmt.Errorf("detecting package manager: %w", derr)
+		}
+
+		// RUNNER_PACKAGE_MANAGER forces a specific choice for CI reproducibility, so it takes
+		// precedence over lockfile detection rather than being overridden by it.
+		packageManager = r.Params.PackageManager
+		if packageManager == "" {
+			packageManager = detected
+		}
+		if packageManager == "" {
+			packageManager = r.dependency
+		}
+		if packageManager == "" {
+			packageManager = packageManagerNpm
+		}
+
+		installArgs := detectedArgs
+		if packageManager != detected || len(installArgs) == 0 {
+			installArgs = installArgsFor(packageManager, runPath)
+		}
+
 		// be gentle to different cypress versions, run from local npm deps
-		out, err := executor.Run(runPath, r.dependency, nil, "install")
+		out, err := executor.Run(runPath, packageManager, nil, installArgs...)
 		if err != nil {
-			return result, fmt.Errorf("%s install error: %w\n\n%s", r.dependency, err, out)
+			return result, fmt.Errorf("%s install error: %w\n\n%s", packageManager, err, out)
 		}
 	} else if errors.Is(err, os.ErrNotExist) {
 		return result, fmt.Errorf("package.json file not found: %w", err)
@@ -140,25 +200,131 @@ func (r *NxRunner) Run(execution testkube.Execution) (result testkube.ExecutionR
 		args = append(args, "--env", strings.Join(envVars, ","))
 	}
 
-	command := fmt.Sprintf("./node_modules/.bin/nx run %s --target=%s", r.Params.NxCommand, r.Params.NxProject)
+	preRunScript := execution.PreRunScript
+	if preRunScript == "" {
+		preRunScript = r.Params.PreRunScript
+	}
+
+	postRunScript := execution.PostRunScript
+	if postRunScript == "" {
+		postRunScript = r.Params.PostRunScript
+	}
+
+	argsMode := execution.ArgsMode
+	if argsMode == "" {
+		argsMode = ArgsModeAppend
+	}
+
+	var affectedProjects []string
+	var command string
+	if argsMode == ArgsModeOverride {
+		command, args, err = commandOverride(execution)
+		if err != nil {
+			return result, err
+		}
+	} else if r.Params.NxMode == NxModeAffected {
+		base, head, rerr := r.resolveAffectedRefs(runPath, execution)
+		if rerr != nil {
+			return result, rerr
+		}
+
+		affectedProjects, err = r.listAffectedProjects(runPath, packageManager, base, head)
+		if err != nil {
+			return result, err
+		}
+
+		nxBin, nxArgs := nxBinary(packageManager)
+		command = nxBin
+		args = append(append(nxArgs, "affected", fmt.Sprintf("--target=%s", r.Params.NxCommand), fmt.Sprintf("--base=%s", base), fmt.Sprintf("--head=%s", head)), args...)
+	} else {
+		nxBin, nxArgs := nxBinary(packageManager)
+		command = nxBin
+		args = append(append(nxArgs, "run", r.Params.NxProject, fmt.Sprintf("--target=%s", r.Params.NxCommand)), args...)
+	}
+
+	cdeventsProject := r.Params.NxProject
+	if cdeventsProject == "" {
+		cdeventsProject = NxModeAffected
+	}
+
+	// lifecycleErrors holds non-fatal errors (failed cdevents publishes, a failed post-run
+	// script) that must survive the `result = MapJunitToExecutionResults(...)` reassignment
+	// below instead of being attached to a `result` value that gets thrown away.
+	var lifecycleErrors []error
+
+	logrus.Debug("start: publishing cdevents queued/started events")
+	if cerr := r.CDEvents.PublishQueued(execution, cdeventsProject); cerr != nil {
+		logrus.Errorf("cdevents: %v", cerr)
+		lifecycleErrors = append(lifecycleErrors, cerr)
+	}
+	if cerr := r.CDEvents.PublishStarted(execution, cdeventsProject); cerr != nil {
+		logrus.Errorf("cdevents: %v", cerr)
+		lifecycleErrors = append(lifecycleErrors, cerr)
+	}
+	logrus.Debug("end: publishing cdevents queued/started events")
+
+	if preRunScript != "" {
+		logrus.Debug("start: running pre-run script")
+		preOut, perr := executor.Run(runPath, preRunScript, envManager)
+		if perr != nil {
+			result = result.WithErrors(lifecycleErrors...)
+			return result.Err(fmt.Errorf("pre-run script error: %w\n\n%s", perr, envManager.Obfuscate(preOut))), nil
+		}
+		logrus.Debug("end: running pre-run script")
+	}
+
 	out, err := executor.Run(runPath, command, envManager, args...)
+
+	if postRunScript != "" {
+		logrus.Debug("start: running post-run script")
+		postOut, perr := executor.Run(runPath, postRunScript, envManager)
+		out = append(out, postOut...)
+		if perr != nil {
+			lifecycleErrors = append(lifecycleErrors, fmt.Errorf("post-run script error: %w\n\n%s", perr, envManager.Obfuscate(postOut)))
+		}
+		logrus.Debug("end: running post-run script")
+	}
+
+	out = envManager.Obfuscate(out)
+
 	if err != nil {
+		result = result.WithErrors(lifecycleErrors...)
+		result.Output = string(out)
 		return result.Err(err), nil
 	}
 
-	out = envManager.Obfuscate(out)
-	suites, serr := junit.Ingest(out)
+	var suites []junit.Suite
+	var serr error
+	if r.Params.NxMode == NxModeAffected {
+		// `nx affected` writes one JUnit file per project instead of a single stream
+		suites, serr = r.collectAffectedJunitSuites(runPath)
+	} else {
+		suites, serr = junit.Ingest(out)
+	}
 	result = MapJunitToExecutionResults(out, suites)
+	result = result.WithErrors(lifecycleErrors...)
+
+	for _, project := range affectedProjects {
+		result.Steps = append(result.Steps, testkube.ExecutionStepResult{
+			Name:   fmt.Sprintf("affected: %s", project),
+			Status: *result.Status,
+		})
+	}
+
+	logrus.Debug("start: publishing cdevents finished events")
+	if cerr := r.CDEvents.PublishFinished(execution, cdeventsProject, suites); cerr != nil {
+		logrus.Errorf("cdevents: %v", cerr)
+		result = result.WithErrors(cerr)
+	}
+	logrus.Debug("end: publishing cdevents finished events")
 
 	// scrape artifacts first even if there are errors above
 	if r.Params.ScrapperEnabled {
-		directories := []string{
-			filepath.Join(runPath, "cypress/videos"),
-			filepath.Join(runPath, "cypress/screenshots"),
-		}
-		err := r.Scraper.Scrape(execution.Id, directories)
-		if err != nil {
-			return result.WithErrors(fmt.Errorf("scrape artifacts error: %w", err)), nil
+		directories := r.resolveArtifactDirs(runPath, execution)
+		if len(directories) > 0 {
+			if err := r.Scraper.Scrape(execution.Id, directories); err != nil {
+				return result.WithErrors(fmt.Errorf("scrape artifacts error: %w", err)), nil
+			}
 		}
 	}
 
@@ -170,6 +336,109 @@ func (r *NxRunner) Run(execution testkube.Execution) (result testkube.ExecutionR
 	}, nil*/
 }
 
+// resolveAffectedRefs figures out the base/head revisions used for `nx affected`, falling back to
+// the execution's repository commit and the merge-base with the default branch when unset.
+func (r *NxRunner) resolveAffectedRefs(runPath string, execution testkube.Execution) (base string, head string, err error) {
+	base = r.Params.NxBase
+	head = r.Params.NxHead
+
+	if head == "" {
+		head = execution.Content.Repository.Commit
+	}
+
+	if base == "" {
+		// the remote's default branch, as recorded in origin/HEAD, or origin/main when unknown
+		defaultBranch := "origin/main"
+		if ref, serr := executor.Run(runPath, "git", nil, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); serr == nil && strings.TrimSpace(string(ref)) != "" {
+			defaultBranch = strings.TrimSpace(string(ref))
+		}
+
+		out, merr := executor.Run(runPath, "git", nil, "merge-base", defaultBranch, "HEAD")
+		if merr != nil {
+			return "", "", fmt.Errorf("resolving merge-base for affected mode: %w\n\n%s", merr, out)
+		}
+		base = strings.TrimSpace(string(out))
+	}
+
+	return base, head, nil
+}
+
+// listAffectedProjects returns the Nx projects affected between base and head, used to report
+// which projects ran alongside the merged JUnit results.
+func (r *NxRunner) listAffectedProjects(runPath, packageManager, base, head string) ([]string, error) {
+	nxBin, nxArgs := nxBinary(packageManager)
+	args := append(nxArgs, "show", "projects", "--affected", fmt.Sprintf("--base=%s", base), fmt.Sprintf("--head=%s", head))
+	out, err := executor.Run(runPath, nxBin, nil, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing affected projects: %w\n\n%s", err, out)
+	}
+
+	var projects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			projects = append(projects, line)
+		}
+	}
+
+	return projects, nil
+}
+
+// commandOverride resolves the command and args to run when args_mode is "override", merging
+// execution.Command/execution.Args the same way the rest of the executor ecosystem does.
+func commandOverride(execution testkube.Execution) (command string, args []string, err error) {
+	if len(execution.Command) == 0 {
+		return "", nil, errors.New("execution command must be defined when args_mode is \"override\"")
+	}
+
+	command, args = executor.MergeCommandAndArgs(execution.Command, execution.Args)
+	return command, args, nil
+}
+
+// skippedJunitSearchDirs are vendor/VCS directories excluded from the JUnit glob below: they
+// can be huge (node_modules) and may ship their own fixture files matching junit*.xml, which
+// would otherwise get ingested as if they were real test results.
+var skippedJunitSearchDirs = map[string]bool{
+	"node_modules": true,
+	".git":         true,
+}
+
+// collectAffectedJunitSuites globs runPath for the per-project JUnit files that `nx affected`
+// produces and merges them into a single suite list.
+func (r *NxRunner) collectAffectedJunitSuites(runPath string) ([]junit.Suite, error) {
+	var files []string
+	err := filepath.WalkDir(runPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skippedJunitSearchDirs[d.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		name := d.Name()
+		if matched, _ := filepath.Match("junit*.xml", name); matched {
+			files = append(files, path)
+			return nil
+		}
+		if matched, _ := filepath.Match("*.junit.xml", name); matched {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("globbing junit files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	return junit.IngestFiles(files)
+}
+
 // Validate checks if Execution has valid data in context of Nx executor
 // Nx executor runs currently only based on nx project
 func (r *NxRunner) Validate(execution testkube.Execution) error {
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/kubeshop/testkube/pkg/api/v1/testkube"
+)
+
+func TestResolveAffectedRefs_ExplicitParamsSkipGitLookup(t *testing.T) {
+	r := &NxRunner{Params: Params{NxBase: "base-ref", NxHead: "head-ref"}}
+	execution := testkube.Execution{
+		Content: &testkube.TestContent{
+			Repository: &testkube.Repository{Commit: "repo-commit", Branch: "main"},
+		},
+	}
+
+	// runPath is not a git repo at all: if resolveAffectedRefs tried to shell out to git here
+	// (because base wasn't already set) this would fail.
+	base, head, err := r.resolveAffectedRefs(t.TempDir(), execution)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if base != "base-ref" || head != "head-ref" {
+		t.Errorf("got base=%q head=%q, want base=%q head=%q", base, head, "base-ref", "head-ref")
+	}
+}
+
+func TestResolveAffectedRefs_HeadFallsBackToRepositoryCommit(t *testing.T) {
+	r := &NxRunner{Params: Params{NxBase: "base-ref"}}
+	execution := testkube.Execution{
+		Content: &testkube.TestContent{
+			Repository: &testkube.Repository{Commit: "repo-commit", Branch: "main"},
+		},
+	}
+
+	_, head, err := r.resolveAffectedRefs(t.TempDir(), execution)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if head != "repo-commit" {
+		t.Errorf("head = %q, want %q", head, "repo-commit")
+	}
+}
+
+func TestCommandOverride_MergesCommandAndArgs(t *testing.T) {
+	execution := testkube.Execution{
+		Command: []string{"nx", "test", "my-lib"},
+		Args:    []string{"--coverage"},
+	}
+
+	command, args, err := commandOverride(execution)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if command != "nx" {
+		t.Errorf("command = %q, want %q", command, "nx")
+	}
+	wantArgs := []string{"test", "my-lib", "--coverage"}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("args = %v, want %v", args, wantArgs)
+	}
+	for i := range wantArgs {
+		if args[i] != wantArgs[i] {
+			t.Errorf("args = %v, want %v", args, wantArgs)
+		}
+	}
+}
+
+func TestCommandOverride_ErrorsWhenCommandEmpty(t *testing.T) {
+	if _, _, err := commandOverride(testkube.Execution{}); err == nil {
+		t.Fatal("expected an error when execution.Command is empty")
+	}
+}